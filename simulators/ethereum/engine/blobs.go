@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/protolambda/go-kzg/eth"
+	"github.com/protolambda/ztyp/view"
+)
+
+// Transaction parameters for the blob-tx helpers below. This module does
+// not (yet) have shared transaction-building scaffolding of its own, so
+// these are kept local to this file rather than borrowed from the separate
+// simulators/ethereum/rpc module.
+var (
+	blobTxChainID   = big.NewInt(1)
+	blobTxGasPrice  = big.NewInt(30000000000)
+	blobTxGasTipCap = big.NewInt(1000000000)
+)
+
+// createBlobTransaction builds a numBlobs-blob transaction signed by
+// sourceAddr, mirroring the construction in the rpc simulator's
+// createBlobTransactionN but kept local to this module.
+func createBlobTransaction(t *TestEnv, nonce uint64, numBlobs int, amount *big.Int, sourceAddr common.Address, targetAddr common.Address) *types.Transaction {
+	gasLimit := 210000
+
+	var blobs types.Blobs
+	for i := 0; i < numBlobs; i++ {
+		blobs = append(blobs, randomBlob(nonce, i))
+	}
+	kzgCommitments, versionedHashes, aggregatedProof, err := blobs.ComputeCommitmentsAndAggregatedProof()
+	if err != nil {
+		t.Fatalf("unable to compute kzg commitments: %v", err)
+	}
+	txData := types.SignedBlobTx{
+		Message: types.BlobTxMessage{
+			ChainID:             view.MustUint256(blobTxChainID.String()),
+			Nonce:               view.Uint64View(nonce),
+			Gas:                 view.Uint64View(gasLimit),
+			GasFeeCap:           view.MustUint256(blobTxGasPrice.String()),
+			GasTipCap:           view.MustUint256(blobTxGasTipCap.String()),
+			MaxFeePerDataGas:    view.MustUint256("3000000000"), // needs to be at least the min fee
+			Value:               view.MustUint256(amount.String()),
+			To:                  types.AddressOptionalSSZ{Address: (*types.AddressSSZ)(&targetAddr)},
+			BlobVersionedHashes: versionedHashes,
+		},
+	}
+	wrapData := types.BlobTxWrapData{
+		BlobKzgs:           kzgCommitments,
+		Blobs:              blobs,
+		KzgAggregatedProof: aggregatedProof,
+	}
+	rawTx := types.NewTx(&txData, types.WithTxWrapData(&wrapData))
+	tx, err := t.Vault.signTransaction(sourceAddr, rawTx)
+	if err != nil {
+		t.Fatalf("Unable to sign blob tx: %v", err)
+	}
+	return tx
+}
+
+// randomBlob fills a blob with deterministic pseudo-random field elements,
+// clearing the top byte of each element to keep it canonical.
+func randomBlob(nonce uint64, index int) types.Blob {
+	src := rand.New(rand.NewSource(int64(nonce)<<16 | int64(index)))
+	var blob types.Blob
+	for i := range blob {
+		src.Read(blob[i][:])
+		blob[i][0] = 0
+	}
+	return blob
+}
+
+// blobsBundleTest drives the execution client through the Engine API for a
+// post-Cancun payload carrying blob transactions: after submitting blob
+// transactions over JSON-RPC, it triggers payload building via
+// engine_forkchoiceUpdatedV2, retrieves the built payload and its blobs
+// bundle via engine_getPayloadV3, and submits the payload back via
+// engine_newPayloadV3.
+func blobsBundleTest(t *TestEnv) {
+	payload, bundle := t.buildBlobsPayload(4)
+
+	if err := verifyBlobsBundle(payload, bundle); err != nil {
+		t.Fatalf("invalid blobs bundle: %v", err)
+	}
+
+	status, err := t.Engine.NewPayloadV3(t.Ctx(), payload, payload.BlobVersionedHashes())
+	if err != nil {
+		t.Fatalf("engine_newPayloadV3 failed: %v", err)
+	}
+	if status.Status != "VALID" {
+		t.Fatalf("expected VALID status from engine_newPayloadV3, got %s", status.Status)
+	}
+}
+
+// buildBlobsPayload submits numTxs blob transactions, drives the client
+// through engine_forkchoiceUpdatedV2 and engine_getPayloadV3 to build a
+// payload around them, and returns the resulting payload and blobs bundle.
+func (t *TestEnv) buildBlobsPayload(numTxs int) (*ExecutionPayload, *BlobsBundle) {
+	var (
+		sourceAddr  = t.Vault.createAccount(t, big.NewInt(params.Ether))
+		sourceNonce = uint64(0)
+		targetAddr  = t.Vault.createAccount(t, nil)
+	)
+
+	for i := 0; i < numTxs; i++ {
+		tx := createBlobTransaction(t, sourceNonce, 1, big.NewInt(0), sourceAddr, targetAddr)
+		sourceNonce++
+		if err := t.Eth.SendTransaction(t.Ctx(), tx); err != nil {
+			t.Fatalf("Unable to send blob transaction: %v", err)
+		}
+	}
+
+	fcState := t.CLMock.LatestForkchoiceState()
+	payloadAttributes := t.CLMock.NextPayloadAttributes()
+
+	fcResp, err := t.Engine.ForkchoiceUpdatedV2(t.Ctx(), fcState, payloadAttributes)
+	if err != nil {
+		t.Fatalf("engine_forkchoiceUpdatedV2 failed: %v", err)
+	}
+	if fcResp.PayloadID == nil {
+		t.Fatalf("engine_forkchoiceUpdatedV2 did not return a payload ID")
+	}
+
+	built, err := t.Engine.GetPayloadV3(t.Ctx(), *fcResp.PayloadID)
+	if err != nil {
+		t.Fatalf("engine_getPayloadV3 failed: %v", err)
+	}
+	return built.ExecutionPayload, built.BlobsBundle
+}
+
+// verifyBlobsBundle checks that every versioned hash referenced by the
+// payload's transactions has a matching KZG commitment in the bundle, that
+// each commitment hashes to its versioned hash, and that the bundle's
+// aggregated proof verifies against the bundle's blobs and commitments.
+func verifyBlobsBundle(payload *ExecutionPayload, bundle *BlobsBundle) error {
+	hashes := payload.BlobVersionedHashes()
+	if len(hashes) != len(bundle.Commitments) {
+		return fmt.Errorf("commitment count mismatch: %d versioned hashes, %d commitments", len(hashes), len(bundle.Commitments))
+	}
+	if len(bundle.Blobs) != len(bundle.Commitments) {
+		return fmt.Errorf("blob count mismatch: %d blobs, %d commitments", len(bundle.Blobs), len(bundle.Commitments))
+	}
+	for i, commitment := range bundle.Commitments {
+		if got := commitment.ComputeVersionedHash(); got != hashes[i] {
+			return fmt.Errorf("commitment %d maps to versioned hash %v, want %v", i, got, hashes[i])
+		}
+	}
+	ok, err := eth.VerifyAggregateKZGProof(bundle.Blobs, bundle.Commitments, eth.KZGProof(bundle.Proof))
+	if err != nil {
+		return fmt.Errorf("unable to verify aggregated proof: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("aggregated proof does not verify against bundle")
+	}
+	return nil
+}
+
+// blobsBundleWrongOrderTest shuffles the order of the blobs within an
+// otherwise valid bundle and asserts engine_newPayloadV3 rejects the
+// resulting payload. Uses two transactions so the bundle has at least two
+// distinct blobs to swap.
+func blobsBundleWrongOrderTest(t *TestEnv) {
+	payload, bundle := t.buildBlobsPayload(2)
+	bundle.Blobs[0], bundle.Blobs[len(bundle.Blobs)-1] = bundle.Blobs[len(bundle.Blobs)-1], bundle.Blobs[0]
+
+	status, err := t.Engine.NewPayloadV3(t.Ctx(), payload, payload.BlobVersionedHashes())
+	if err == nil && status.Status == "VALID" {
+		t.Fatalf("expected engine_newPayloadV3 to reject a payload with reordered blobs")
+	}
+}
+
+// blobsBundleMissingCommitmentTest drops a commitment from an otherwise
+// valid bundle and asserts engine_newPayloadV3 rejects the resulting
+// payload.
+func blobsBundleMissingCommitmentTest(t *TestEnv) {
+	payload, bundle := t.buildBlobsPayload(2)
+	bundle.Commitments = bundle.Commitments[:len(bundle.Commitments)-1]
+
+	status, err := t.Engine.NewPayloadV3(t.Ctx(), payload, payload.BlobVersionedHashes())
+	if err == nil && status.Status == "VALID" {
+		t.Fatalf("expected engine_newPayloadV3 to reject a payload with a missing commitment")
+	}
+}
+
+// blobsBundleMismatchedVersionedHashesTest submits a valid payload and
+// bundle but passes an expectedBlobVersionedHashes list that does not match
+// the payload's transactions, and asserts engine_newPayloadV3 rejects it.
+func blobsBundleMismatchedVersionedHashesTest(t *TestEnv) {
+	payload, _ := t.buildBlobsPayload(1)
+	wrongHashes := append([]common.Hash{}, payload.BlobVersionedHashes()...)
+	wrongHashes[0][0] ^= 0xff
+
+	status, err := t.Engine.NewPayloadV3(t.Ctx(), payload, wrongHashes)
+	if err == nil && status.Status == "VALID" {
+		t.Fatalf("expected engine_newPayloadV3 to reject a payload with mismatched expectedBlobVersionedHashes")
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// maxBlobsPerTxTest submits a transaction carrying exactly maxBlobsPerTx
+// blobs and asserts that the client accepts and includes it.
+func maxBlobsPerTxTest(t *TestEnv) {
+	var (
+		sourceAddr = t.Vault.createAccount(t, big.NewInt(params.Ether))
+		targetAddr = t.Vault.createAccount(t, nil)
+	)
+
+	tx := createBlobTransactionN(t, 0, maxBlobsPerTx, big.NewInt(0), sourceAddr, targetAddr)
+	if err := t.Eth.SendTransaction(t.Ctx(), tx); err != nil {
+		t.Fatalf("Unable to send transaction with %d blobs: %v", maxBlobsPerTx, err)
+	}
+
+	receipt, err := waitForReceipt(t.Ctx(), t.Eth, tx.Hash())
+	if err != nil {
+		t.Fatalf("failed to wait for tx (%v) confirmation: %v", tx.Hash(), err)
+	}
+	if numHashes := len(tx.DataHashes()); numHashes != maxBlobsPerTx {
+		t.Fatalf("expected %d versioned hashes on tx, got %d", maxBlobsPerTx, numHashes)
+	}
+	t.Logf("maxBlobsPerTxTest: tx %v included in block %v", tx.Hash(), receipt.BlockNumber)
+}
+
+// exceedsMaxBlobsPerTxTest submits a transaction carrying one more blob than
+// maxBlobsPerTx allows and asserts that the client rejects it before ever
+// broadcasting it to the network.
+func exceedsMaxBlobsPerTxTest(t *TestEnv) {
+	var (
+		sourceAddr = t.Vault.createAccount(t, big.NewInt(params.Ether))
+		targetAddr = t.Vault.createAccount(t, nil)
+	)
+
+	tx := createBlobTransactionN(t, 0, maxBlobsPerTx+1, big.NewInt(0), sourceAddr, targetAddr)
+	if err := t.Eth.SendTransaction(t.Ctx(), tx); err == nil {
+		t.Fatalf("expected client to reject a transaction with %d blobs, but it was accepted", maxBlobsPerTx+1)
+	}
+}
+
+// maxBlobsPerBlockTest submits enough single- and multi-blob transactions to
+// exceed maxBlobsPerBlock and asserts that the execution client never packs
+// more than that many blobs into a single block.
+func maxBlobsPerBlockTest(t *TestEnv) {
+	var (
+		sourceAddr  = t.Vault.createAccount(t, big.NewInt(params.Ether))
+		sourceNonce = uint64(0)
+		targetAddr  = t.Vault.createAccount(t, nil)
+	)
+
+	// Enough blobs (27), split across single- and multi-blob txs, to
+	// overflow a single block's data-gas budget of maxBlobsPerBlock blobs.
+	blobCounts := []int{1, 2, 1, 2, 1, 2, 1, 2, 1, 2, 1, 2, 1, 2, 1, 2, 1, 2}
+
+	var txs []*types.Transaction
+	for _, n := range blobCounts {
+		txs = append(txs, createBlobTransactionN(t, sourceNonce, n, big.NewInt(0), sourceAddr, targetAddr))
+		sourceNonce++
+	}
+
+	for _, tx := range txs {
+		if err := t.Eth.SendTransaction(t.Ctx(), tx); err != nil {
+			t.Fatalf("Error sending tx (%v): %v", tx.Hash(), err)
+		}
+	}
+
+	blockBlobCount := make(map[uint64]int)
+	for _, tx := range txs {
+		receipt, err := waitForReceipt(t.Ctx(), t.Eth, tx.Hash())
+		if err != nil {
+			t.Fatalf("failed to wait for tx (%v) confirmation: %v", tx.Hash(), err)
+		}
+		blockBlobCount[receipt.BlockNumber.Uint64()] += len(tx.DataHashes())
+	}
+
+	for blockNum, count := range blockBlobCount {
+		if count > maxBlobsPerBlock {
+			t.Fatalf("block %d packed %d blobs, exceeding maxBlobsPerBlock=%d", blockNum, count, maxBlobsPerBlock)
+		}
+	}
+}
+
+// invalidBlobVersionedHashesTest submits a blob transaction whose declared
+// BlobVersionedHashes do not match the KZG commitments carried in the
+// network wrapper, and asserts that the client rejects it.
+func invalidBlobVersionedHashesTest(t *TestEnv) {
+	var (
+		sourceAddr = t.Vault.createAccount(t, big.NewInt(params.Ether))
+		targetAddr = t.Vault.createAccount(t, nil)
+	)
+
+	tx := createBlobTransactionWithOpts(t, 0, 1, big.NewInt(0), sourceAddr, targetAddr, &blobTxOpts{corruptVersionedHash: true})
+
+	if err := t.Eth.SendTransaction(t.Ctx(), tx); err == nil {
+		t.Fatalf("expected client to reject a transaction with mismatched blob versioned hashes, but it was accepted")
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// replacementBumpPercent is the minimum percentage by which a replacement
+// blob transaction's fee caps must exceed the original for the pool to
+// accept the replacement.
+const replacementBumpPercent = 10
+
+// createReplacementBlobTransaction builds a blob tx with the same nonce as
+// an earlier one, bumping GasTipCap and MaxFeePerDataGas by at least
+// replacementBumpPercent so that it is eligible to replace it in the pool.
+func createReplacementBlobTransaction(t *TestEnv, original *types.Transaction, sourceAddr common.Address, targetAddr common.Address) *types.Transaction {
+	opts := &blobTxOpts{
+		gasTipCap:        bumpByPercent(gasTipCap, replacementBumpPercent),
+		maxFeePerDataGas: bumpByPercent(big.NewInt(3000000000), replacementBumpPercent),
+	}
+	return createBlobTransactionWithOpts(t, original.Nonce(), 1, original.Value(), sourceAddr, targetAddr, opts)
+}
+
+func bumpByPercent(v *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(v, big.NewInt(100+percent))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+// blobTransactionReplacementTest submits a blob tx, then replaces it with
+// one carrying the same nonce but higher fee caps, and asserts only the
+// replacement is ever included on-chain.
+func blobTransactionReplacementTest(t *TestEnv) {
+	var (
+		sourceAddr = t.Vault.createAccount(t, big.NewInt(params.Ether))
+		targetAddr = t.Vault.createAccount(t, nil)
+	)
+
+	original := createBlobTransaction(t, 0, big.NewInt(0), sourceAddr, targetAddr)
+	if err := t.Eth.SendTransaction(t.Ctx(), original); err != nil {
+		t.Fatalf("Unable to send original transaction: %v", err)
+	}
+
+	// Guard against a fast block-period client mining the original before
+	// the replacement is ever submitted, which would make the "only the
+	// replacement lands" assertion below meaningless.
+	if _, err := t.Eth.TransactionReceipt(t.Ctx(), original.Hash()); err != ethereum.NotFound {
+		if err == nil {
+			t.Fatalf("original transaction %v was already mined before the replacement could be submitted; client block period is too fast for this test", original.Hash())
+		}
+		t.Fatalf("unexpected error checking original transaction receipt: %v", err)
+	}
+
+	replacement := createReplacementBlobTransaction(t, original, sourceAddr, targetAddr)
+	if err := t.Eth.SendTransaction(t.Ctx(), replacement); err != nil {
+		t.Fatalf("Unable to send replacement transaction: %v", err)
+	}
+
+	receipt, err := waitForReceipt(t.Ctx(), t.Eth, replacement.Hash())
+	if err != nil {
+		t.Fatalf("failed to wait for replacement tx (%v) confirmation: %v", replacement.Hash(), err)
+	}
+	t.Logf("blobTransactionReplacementTest: replacement %v included in block %v", replacement.Hash(), receipt.BlockNumber)
+
+	if _, err := t.Eth.TransactionReceipt(t.Ctx(), original.Hash()); err == nil {
+		t.Fatalf("original transaction %v was included on-chain despite being replaced", original.Hash())
+	}
+}
+
+// maxProbedAccountBlobTxs bounds how many pending blob transactions
+// blobTransactionPoolEvictionTest will queue up on a single account while
+// probing for the pool's per-account cap; it is a safety backstop, not an
+// assumed cap.
+const maxProbedAccountBlobTxs = 64
+
+// blobTransactionPoolEvictionTest submits single-blob transactions from one
+// sender with MaxFeePerDataGas strictly decreasing as the nonce increases,
+// until the pool starts rejecting them outright, discovering the
+// client's actual per-account pending blob-tx cap instead of assuming one.
+// It then asserts that every transaction up to that cap is accepted and
+// eventually mined, and that nothing past it ever was.
+func blobTransactionPoolEvictionTest(t *TestEnv) {
+	var (
+		sourceAddr = t.Vault.createAccount(t, big.NewInt(params.Ether))
+		targetAddr = t.Vault.createAccount(t, nil)
+		basePrice  = big.NewInt(3000000000)
+		// priceStep is kept small enough that decreasing it
+		// maxProbedAccountBlobTxs+2 times (the probing loop plus the two
+		// past-cap confirmations below) never drives maxFeePerDataGas
+		// non-positive.
+		priceStep = big.NewInt(40000000)
+	)
+
+	var txs []*types.Transaction
+	accountCap := -1
+	for i := 0; i < maxProbedAccountBlobTxs; i++ {
+		price := new(big.Int).Sub(basePrice, new(big.Int).Mul(priceStep, big.NewInt(int64(i))))
+		tx := createBlobTransactionWithOpts(t, uint64(i), 1, big.NewInt(0), sourceAddr, targetAddr, &blobTxOpts{maxFeePerDataGas: price})
+		if err := t.Eth.SendTransaction(t.Ctx(), tx); err != nil {
+			accountCap = i
+			break
+		}
+		txs = append(txs, tx)
+	}
+	if accountCap < 0 {
+		t.Fatalf("pool accepted %d pending blob transactions from a single account without ever rejecting one; unable to determine its per-account cap", maxProbedAccountBlobTxs)
+	}
+	if accountCap == 0 {
+		t.Fatalf("pool rejected the very first blob transaction from a fresh account")
+	}
+	t.Logf("blobTransactionPoolEvictionTest: discovered a per-account pending blob-tx cap of %d", accountCap)
+
+	// A couple more higher-nonce, lower-priced transactions past the
+	// discovered cap must also be rejected, confirming it isn't a fluke.
+	// Start past accountCap itself, whose rejection was already observed
+	// in the discovery loop above.
+	for i := accountCap + 1; i < accountCap+3; i++ {
+		price := new(big.Int).Sub(basePrice, new(big.Int).Mul(priceStep, big.NewInt(int64(i))))
+		tx := createBlobTransactionWithOpts(t, uint64(i), 1, big.NewInt(0), sourceAddr, targetAddr, &blobTxOpts{maxFeePerDataGas: price})
+		if err := t.Eth.SendTransaction(t.Ctx(), tx); err == nil {
+			t.Fatalf("expected tx %d (nonce %d, lower-priced) to be rejected past the discovered per-account cap of %d, but the pool accepted it", i, i, accountCap)
+		}
+	}
+
+	for _, tx := range txs {
+		if _, err := waitForReceipt(t.Ctx(), t.Eth, tx.Hash()); err != nil {
+			t.Fatalf("failed to wait for tx (%v) confirmation: %v", tx.Hash(), err)
+		}
+	}
+}
+
+// blobTransactionUnderpricedQueuedTest submits a blob tx whose
+// MaxFeePerDataGas is below the current network data_gasprice, confirms it
+// stays queued across several idle blocks, then mines further idle blocks
+// to let the data gasprice decay and asserts the tx is eventually mined.
+func blobTransactionUnderpricedQueuedTest(t *TestEnv) {
+	var (
+		sourceAddr = t.Vault.createAccount(t, big.NewInt(params.Ether))
+		targetAddr = t.Vault.createAccount(t, nil)
+		idleAddr   = t.Vault.createAccount(t, big.NewInt(params.Ether))
+		idleNonce  = uint64(0)
+	)
+
+	tx := createBlobTransactionWithOpts(t, 0, 1, big.NewInt(0), sourceAddr, targetAddr, &blobTxOpts{maxFeePerDataGas: big.NewInt(1)})
+	if err := t.Eth.SendTransaction(t.Ctx(), tx); err != nil {
+		t.Fatalf("Unable to submit underpriced tx: %v", err)
+	}
+
+	const idleBlocksToObserve = 3
+	for i := 0; i < idleBlocksToObserve; i++ {
+		t.mineEmptyBlock(idleAddr, idleNonce)
+		idleNonce++
+		if _, err := t.Eth.TransactionReceipt(t.Ctx(), tx.Hash()); err == nil {
+			t.Fatalf("underpriced blob tx was mined after %d idle block(s), expected it to remain queued", i+1)
+		}
+	}
+
+	const maxIdleBlocks = 64
+	var mined bool
+	for i := 0; i < maxIdleBlocks; i++ {
+		t.mineEmptyBlock(idleAddr, idleNonce)
+		idleNonce++
+		if _, err := t.Eth.TransactionReceipt(t.Ctx(), tx.Hash()); err == nil {
+			mined = true
+			break
+		}
+	}
+	if !mined {
+		t.Fatalf("underpriced blob tx was never mined after %d idle blocks while the data gasprice decayed", maxIdleBlocks)
+	}
+}
+
+// blobTransactionReorgTest mines a blob transaction, triggers a reorg away
+// from the block that included it, and asserts that it re-enters the pool
+// and is eventually re-included with its blob sidecar intact.
+func blobTransactionReorgTest(t *TestEnv) {
+	var (
+		sourceAddr = t.Vault.createAccount(t, big.NewInt(params.Ether))
+		targetAddr = t.Vault.createAccount(t, nil)
+	)
+
+	tx := createBlobTransaction(t, 0, big.NewInt(0), sourceAddr, targetAddr)
+	if err := t.Eth.SendTransaction(t.Ctx(), tx); err != nil {
+		t.Fatalf("Unable to send transaction: %v", err)
+	}
+	originalReceipt, err := waitForReceipt(t.Ctx(), t.Eth, tx.Hash())
+	if err != nil {
+		t.Fatalf("failed to wait for tx (%v) confirmation: %v", tx.Hash(), err)
+	}
+
+	if err := t.triggerReorgAwayFrom(originalReceipt.BlockHash); err != nil {
+		t.Fatalf("failed to trigger reorg: %v", err)
+	}
+
+	newReceipt, err := waitForReceipt(t.Ctx(), t.Eth, tx.Hash())
+	if err != nil {
+		t.Fatalf("blob tx (%v) was not re-included after reorg: %v", tx.Hash(), err)
+	}
+	if newReceipt.BlockHash == originalReceipt.BlockHash {
+		t.Fatalf("expected tx to be re-included in a new block after reorg, got the same block %v", newReceipt.BlockHash)
+	}
+
+	block, err := t.Eth.BlockByHash(t.Ctx(), newReceipt.BlockHash)
+	if err != nil {
+		t.Fatalf("unable to retrieve block: %v", err)
+	}
+	var found *types.Transaction
+	for _, blockTx := range block.Transactions() {
+		if blockTx.Hash() == tx.Hash() {
+			found = blockTx
+		}
+	}
+	if found == nil {
+		t.Fatalf("re-included tx missing from new block")
+	}
+	if len(found.DataHashes()) != len(tx.DataHashes()) {
+		t.Fatalf("re-included tx lost its blob sidecar: expected %d versioned hashes, got %d", len(tx.DataHashes()), len(found.DataHashes()))
+	}
+}
+
+// reorgPollInterval and reorgTimeout bound the polling loops in
+// triggerReorgAwayFrom, mirroring the 1-second poll used by waitForReceipt
+// elsewhere in this package.
+const (
+	reorgPollInterval = time.Second
+	reorgTimeout      = 2 * time.Minute
+)
+
+// triggerReorgAwayFrom starts a second client, lets it build a heavier
+// chain that does not contain staleHead, then peers it with the client
+// under test so the latter reorgs onto the heavier chain.
+func (t *TestEnv) triggerReorgAwayFrom(staleHead common.Hash) error {
+	second := t.StartClient()
+
+	staleHeadBlock, err := t.Eth.BlockByHash(t.Ctx(), staleHead)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve stale head block: %w", err)
+	}
+
+	deadline := time.Now().Add(reorgTimeout)
+	for {
+		head, err := second.Eth.HeaderByNumber(t.Ctx(), nil)
+		if err != nil {
+			return fmt.Errorf("unable to retrieve second client's head: %w", err)
+		}
+		if head.Number.Cmp(staleHeadBlock.Number()) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for second client to build a chain heavier than block %d", staleHeadBlock.NumberU64())
+		}
+		time.Sleep(reorgPollInterval)
+	}
+
+	if err := t.Client.AddPeer(t.Ctx(), second.EnodeURL()); err != nil {
+		return fmt.Errorf("unable to peer with second client: %w", err)
+	}
+
+	deadline = time.Now().Add(reorgTimeout)
+	for {
+		canonical, err := t.Eth.BlockByNumber(t.Ctx(), staleHeadBlock.Number())
+		if err == nil && canonical.Hash() != staleHead {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for client to reorg away from block %v", staleHead)
+		}
+		time.Sleep(reorgPollInterval)
+	}
+}
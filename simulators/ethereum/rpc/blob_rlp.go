@@ -0,0 +1,259 @@
+package main
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// rlpBlobTxType is the EIP-2718 transaction type byte for a blob
+// transaction (types.BlobTxType), shared by both the SSZ and RLP
+// encodings. Type 4 is reserved; this pinned go-ethereum fork assigns
+// blob transactions type 5, not the 3 used by later EIP-4844 drafts.
+const rlpBlobTxType = 5
+
+// blobTxEncoding selects which wire-format encoding a blob transaction
+// should be built with. Clients are required to accept blob (type-5)
+// transactions in either encoding.
+type blobTxEncoding int
+
+const (
+	blobTxEncodingSSZ blobTxEncoding = iota
+	blobTxEncodingRLP
+)
+
+func (e blobTxEncoding) String() string {
+	if e == blobTxEncodingRLP {
+		return "rlp"
+	}
+	return "ssz"
+}
+
+// blobTransactionEncodingMatrixTest runs the core blob-tx acceptance
+// scenarios under both the SSZ and RLP wire-format encodings: a basic
+// value transfer re-served via eth_getTransactionByHash, the
+// MAX_BLOBS_PER_TX boundary (both at and one over the limit), and a
+// transaction with mismatched blob versioned hashes. Pool-timing and
+// fee-market scenarios are not wire-format-specific and are exercised only
+// under the default (SSZ) encoding elsewhere in this package.
+func blobTransactionEncodingMatrixTest(t *TestEnv) {
+	for _, encoding := range []blobTxEncoding{blobTxEncodingSSZ, blobTxEncodingRLP} {
+		t.Logf("blobTransactionEncodingMatrixTest: running scenarios with %s encoding", encoding)
+		valueTransferEncodingScenario(t, encoding)
+		maxBlobsPerTxEncodingScenario(t, encoding)
+		exceedsMaxBlobsPerTxEncodingScenario(t, encoding)
+		invalidVersionedHashesEncodingScenario(t, encoding)
+	}
+}
+
+// valueTransferEncodingScenario submits a single-blob value transfer built
+// with encoding and asserts it is mined and re-served correctly via
+// eth_getTransactionByHash.
+func valueTransferEncodingScenario(t *TestEnv, encoding blobTxEncoding) {
+	targetAddr := t.Vault.createAccount(t, nil)
+
+	txHash, numHashes, accepted := sendEncodedBlobTx(t, encoding, 1, big.NewInt(1234), targetAddr, false)
+	if !accepted {
+		t.Fatalf("[%s] Unable to send value-transfer transaction", encoding)
+	}
+
+	receipt, err := waitForReceipt(t.Ctx(), t.Eth, txHash)
+	if err != nil {
+		t.Fatalf("[%s] failed to wait for tx (%v) confirmation: %v", encoding, txHash, err)
+	}
+
+	fetched, _, err := t.Eth.TransactionByHash(t.Ctx(), txHash)
+	if err != nil {
+		t.Fatalf("[%s] eth_getTransactionByHash failed for %v: %v", encoding, txHash, err)
+	}
+	if fetched.Hash() != txHash {
+		t.Fatalf("[%s] re-served tx hash mismatch: want %v, got %v", encoding, txHash, fetched.Hash())
+	}
+	if len(fetched.DataHashes()) != numHashes {
+		t.Fatalf("[%s] re-served tx lost its blob sidecar: expected %d versioned hashes, got %d", encoding, numHashes, len(fetched.DataHashes()))
+	}
+	t.Logf("blobTransactionEncodingMatrixTest: %s-encoded tx %v included in block %v and re-served correctly", encoding, txHash, receipt.BlockNumber)
+}
+
+// maxBlobsPerTxEncodingScenario submits a transaction at the
+// MAX_BLOBS_PER_TX boundary built with encoding and asserts it is accepted
+// and mined.
+func maxBlobsPerTxEncodingScenario(t *TestEnv, encoding blobTxEncoding) {
+	targetAddr := t.Vault.createAccount(t, nil)
+
+	txHash, _, accepted := sendEncodedBlobTx(t, encoding, maxBlobsPerTx, big.NewInt(0), targetAddr, false)
+	if !accepted {
+		t.Fatalf("[%s] Unable to send transaction with %d blobs", encoding, maxBlobsPerTx)
+	}
+	if _, err := waitForReceipt(t.Ctx(), t.Eth, txHash); err != nil {
+		t.Fatalf("[%s] failed to wait for tx (%v) confirmation: %v", encoding, txHash, err)
+	}
+}
+
+// exceedsMaxBlobsPerTxEncodingScenario submits a transaction one blob over
+// MAX_BLOBS_PER_TX built with encoding and asserts the client rejects it.
+func exceedsMaxBlobsPerTxEncodingScenario(t *TestEnv, encoding blobTxEncoding) {
+	targetAddr := t.Vault.createAccount(t, nil)
+
+	_, _, accepted := sendEncodedBlobTx(t, encoding, maxBlobsPerTx+1, big.NewInt(0), targetAddr, false)
+	if accepted {
+		t.Fatalf("[%s] expected client to reject a transaction with %d blobs, but it was accepted", encoding, maxBlobsPerTx+1)
+	}
+}
+
+// invalidVersionedHashesEncodingScenario submits a transaction built with
+// encoding whose declared blob versioned hashes do not match its KZG
+// commitments, and asserts the client rejects it.
+func invalidVersionedHashesEncodingScenario(t *TestEnv, encoding blobTxEncoding) {
+	targetAddr := t.Vault.createAccount(t, nil)
+
+	_, _, accepted := sendEncodedBlobTx(t, encoding, 1, big.NewInt(0), targetAddr, true)
+	if accepted {
+		t.Fatalf("[%s] expected client to reject a transaction with mismatched blob versioned hashes, but it was accepted", encoding)
+	}
+}
+
+// sendEncodedBlobTx builds and submits a numBlobs-blob transaction to
+// targetAddr using encoding, returning the transaction hash, the number of
+// blob versioned hashes it carries, and whether the client accepted it.
+//
+// For blobTxEncodingSSZ this reuses the shared createBlobTransactionWithOpts/Eth.SendTransaction
+// path. For blobTxEncodingRLP, see sendRawRLPBlobTx: the pinned go-ethereum
+// fork has no types.BlobTx, so there is no *types.Transaction to build in
+// the first place, and the RLP envelope and its network wrapper are
+// hand-encoded and submitted as raw bytes via eth_sendRawTransaction.
+func sendEncodedBlobTx(t *TestEnv, encoding blobTxEncoding, numBlobs int, amount *big.Int, targetAddr common.Address, corruptVersionedHash bool) (txHash common.Hash, numHashes int, accepted bool) {
+	if encoding == blobTxEncodingSSZ {
+		sourceAddr := t.Vault.createAccount(t, big.NewInt(params.Ether))
+		tx := createBlobTransactionWithOpts(t, 0, numBlobs, amount, sourceAddr, targetAddr, &blobTxOpts{corruptVersionedHash: corruptVersionedHash})
+		if err := t.Eth.SendTransaction(t.Ctx(), tx); err != nil {
+			return common.Hash{}, 0, false
+		}
+		return tx.Hash(), len(tx.DataHashes()), true
+	}
+	return sendRawRLPBlobTx(t, numBlobs, amount, targetAddr, corruptVersionedHash)
+}
+
+// sendRawRLPBlobTx hand-encodes a blob (type-5) transaction and its network
+// wrapper per the EIP-4844 RLP scheme:
+//
+//	tx_payload_body = [chain_id, nonce, max_priority_fee_per_gas,
+//	    max_fee_per_gas, gas_limit, to, value, data, access_list,
+//	    max_fee_per_data_gas, blob_versioned_hashes, y_parity, r, s]
+//	envelope        = BLOB_TX_TYPE || rlp(tx_payload_body)
+//	network wrapper = BLOB_TX_TYPE || rlp([tx_payload_body, blobs,
+//	    commitments, aggregated_proof])
+//
+// and submits the wrapper via eth_sendRawTransaction. This bypasses
+// t.Vault/*types.Transaction entirely: the pinned go-ethereum fork only
+// represents blob transactions through the SSZ SignedBlobTx type, which
+// has no RLP counterpart, so there is no TxData implementation to hand to
+// types.NewTx or t.Vault.signTransaction for this path. A throwaway
+// account is generated and self-funded instead of going through the vault,
+// since signing the hand-rolled preimage requires direct access to the
+// sender's private key.
+func sendRawRLPBlobTx(t *TestEnv, numBlobs int, amount *big.Int, targetAddr common.Address, corruptVersionedHash bool) (txHash common.Hash, numHashes int, accepted bool) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("unable to generate key for RLP blob tx account: %v", err)
+	}
+	sourceAddr := crypto.PubkeyToAddress(key.PublicKey)
+	fundRLPAccount(t, sourceAddr)
+
+	var blobs types.Blobs
+	for i := 0; i < numBlobs; i++ {
+		blobs = append(blobs, randomBlob(0, i))
+	}
+	kzgCommitments, versionedHashes, aggregatedProof, err := blobs.ComputeCommitmentsAndAggregatedProof()
+	if err != nil {
+		t.Fatalf("unable to compute kzg commitments: %v", err)
+	}
+	if corruptVersionedHash {
+		versionedHashes[0][31] ^= 0xff
+	}
+
+	unsigned := []interface{}{
+		chainID,
+		uint64(0),
+		gasTipCap,
+		gasPrice,
+		uint64(210000),
+		targetAddr,
+		amount,
+		[]byte{},
+		types.AccessList{},
+		big.NewInt(3000000000), // max_fee_per_data_gas, needs to be at least the min fee
+		versionedHashes,
+	}
+	unsignedRLP, err := rlp.EncodeToBytes(unsigned)
+	if err != nil {
+		t.Fatalf("unable to RLP-encode unsigned blob tx payload: %v", err)
+	}
+	signingHash := crypto.Keccak256(append([]byte{rlpBlobTxType}, unsignedRLP...))
+
+	sig, err := crypto.Sign(signingHash, key)
+	if err != nil {
+		t.Fatalf("unable to sign RLP blob tx: %v", err)
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:64])
+	v := big.NewInt(int64(sig[64]))
+
+	signed := append(append([]interface{}{}, unsigned...), v, r, s)
+	signedRLP, err := rlp.EncodeToBytes(signed)
+	if err != nil {
+		t.Fatalf("unable to RLP-encode signed blob tx payload: %v", err)
+	}
+	txHash = crypto.Keccak256Hash(append([]byte{rlpBlobTxType}, signedRLP...))
+
+	flatBlobs := make([][]byte, len(blobs))
+	for i, blob := range blobs {
+		flatBlobs[i] = flattenBlob(blob)
+	}
+	wrapper := []interface{}{signed, flatBlobs, kzgCommitments, aggregatedProof}
+	wrapperRLP, err := rlp.EncodeToBytes(wrapper)
+	if err != nil {
+		t.Fatalf("unable to RLP-encode blob tx network wrapper: %v", err)
+	}
+	raw := append([]byte{rlpBlobTxType}, wrapperRLP...)
+
+	if err := t.Eth.Client().CallContext(t.Ctx(), nil, "eth_sendRawTransaction", hexutil.Encode(raw)); err != nil {
+		return common.Hash{}, 0, false
+	}
+	return txHash, len(versionedHashes), true
+}
+
+// flattenBlob concatenates a blob's field elements into the single flat byte
+// string the EIP-4844 network wrapper expects. types.Blob is an array of
+// 32-byte field elements rather than a flat byte array, so it has no
+// representation rlp.Encode will render as one string on its own.
+func flattenBlob(blob types.Blob) []byte {
+	out := make([]byte, 0, blob.Len()*32)
+	for i := 0; i < blob.Len(); i++ {
+		elem := blob.At(i)
+		out = append(out, elem[:]...)
+	}
+	return out
+}
+
+// fundRLPAccount sends enough value from a vault-funded account to addr for
+// it to act as the sender of a hand-rolled RLP blob transaction.
+func fundRLPAccount(t *TestEnv, addr common.Address) {
+	funder := t.Vault.createAccount(t, big.NewInt(params.Ether))
+	tx := types.NewTransaction(0, addr, big.NewInt(params.Ether/2), 21000, gasPrice, nil)
+	signedTx, err := t.Vault.signTransaction(funder, tx)
+	if err != nil {
+		t.Fatalf("unable to sign RLP account funding tx: %v", err)
+	}
+	if err := t.Eth.SendTransaction(t.Ctx(), signedTx); err != nil {
+		t.Fatalf("unable to send RLP account funding tx: %v", err)
+	}
+	if _, err := waitForReceipt(t.Ctx(), t.Eth, signedTx.Hash()); err != nil {
+		t.Fatalf("failed to wait for RLP account funding tx (%v) confirmation: %v", signedTx.Hash(), err)
+	}
+}
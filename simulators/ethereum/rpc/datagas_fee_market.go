@@ -0,0 +1,204 @@
+package main
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// EIP-4844 data-gas fee market parameters.
+var (
+	minDataGasPrice            = big.NewInt(1)
+	dataGasPriceUpdateFraction = big.NewInt(2225652)
+)
+
+// fakeExponential computes the fake exponential approximation used by the
+// EIP-4844 blob fee market:
+//
+//	fake_exponential(factor, x, d) = output / d, where
+//	numerator_accum starts at factor*d and, for i=1,2,..., is added into
+//	output and then multiplied by x/(i*d), until a term rounds down to
+//	zero.
+func fakeExponential(factor, numerator, denominator *big.Int) *big.Int {
+	i := big.NewInt(1)
+	output := new(big.Int)
+	numeratorAccum := new(big.Int).Mul(factor, denominator)
+
+	for numeratorAccum.Sign() > 0 {
+		output.Add(output, numeratorAccum)
+
+		numeratorAccum.Mul(numeratorAccum, numerator)
+		numeratorAccum.Div(numeratorAccum, denominator)
+		numeratorAccum.Div(numeratorAccum, i)
+		i.Add(i, big.NewInt(1))
+	}
+	return output.Div(output, denominator)
+}
+
+// dataGasPrice derives the per-byte data gas price for a block with the
+// given excess data gas, per the EIP-4844 formula:
+//
+//	data_gasprice(excess) = MIN_DATA_GASPRICE * fake_exponential(excess, DATA_GASPRICE_UPDATE_FRACTION)
+func dataGasPrice(excessDataGas *big.Int) *big.Int {
+	return fakeExponential(minDataGasPrice, excessDataGas, dataGasPriceUpdateFraction)
+}
+
+// dataGasFeeMarketTest drives excess data gas up via bursts of blob
+// transactions and back down via idle blocks, re-deriving the expected data
+// gasprice at each block from the parent's excessDataGas and comparing it
+// against what the client actually charged senders.
+func dataGasFeeMarketTest(t *TestEnv) {
+	var (
+		sourceAddr  = t.Vault.createAccount(t, big.NewInt(params.Ether))
+		sourceNonce = uint64(0)
+		targetAddr  = t.Vault.createAccount(t, nil)
+	)
+
+	// Burst enough blob txs to push excess data gas up, then go idle for a
+	// few blocks so the client has to let it fall back down again.
+	burstSizes := []int{8, 8, 8, 0, 0, 0, 0}
+
+	var allBlocks []*types.Block
+	for _, burst := range burstSizes {
+		if burst == 0 {
+			block := t.mineEmptyBlock(sourceAddr, sourceNonce)
+			sourceNonce++
+			allBlocks = append(allBlocks, block)
+			continue
+		}
+
+		txs := make([]*types.Transaction, burst)
+		for i := range txs {
+			txs[i] = createBlobTransaction(t, sourceNonce, big.NewInt(0), sourceAddr, targetAddr)
+			sourceNonce++
+		}
+
+		blocks := sendAndCollectBlocks(t, txs)
+		allBlocks = append(allBlocks, blocks...)
+	}
+
+	sort.Slice(allBlocks, func(i, j int) bool {
+		return allBlocks[i].Number().Uint64() < allBlocks[j].Number().Uint64()
+	})
+
+	for _, block := range allBlocks {
+		parent, err := t.Eth.BlockByHash(t.Ctx(), block.ParentHash())
+		if err != nil {
+			t.Fatalf("Error getting parent block: %v", err)
+		}
+		parentExcessDataGas := new(big.Int)
+		if e := parent.ExcessDataGas(); e != nil {
+			parentExcessDataGas.Set(e)
+		}
+
+		expectedPrice := dataGasPrice(parentExcessDataGas)
+
+		for _, tx := range block.Transactions() {
+			numHashes := len(tx.DataHashes())
+			if numHashes == 0 {
+				continue
+			}
+			charged := actualDataGasCharged(t, tx)
+			expectedCharge := new(big.Int).Mul(expectedPrice, big.NewInt(int64(numHashes*params.DataGasPerBlob)))
+			if charged.Cmp(expectedCharge) != 0 {
+				t.Fatalf("block %d: unexpected data gas charge for tx %v: expected %v, got %v (parent excessDataGas=%v)", block.NumberU64(), tx.Hash(), expectedCharge, charged, parentExcessDataGas)
+			}
+		}
+	}
+}
+
+// actualDataGasCharged returns the amount debited from the sender's balance
+// that is attributable to the data gas fee of tx, derived from the sender's
+// balance delta around the transaction's block.
+func actualDataGasCharged(t *TestEnv, tx *types.Transaction) *big.Int {
+	receipt, err := t.Eth.TransactionReceipt(t.Ctx(), tx.Hash())
+	if err != nil {
+		t.Fatalf("Unable to retrieve receipt for %v: %v", tx.Hash(), err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	if err != nil {
+		t.Fatalf("Unable to recover sender for %v: %v", tx.Hash(), err)
+	}
+
+	before, err := t.Eth.BalanceAt(t.Ctx(), sender, new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1)))
+	if err != nil {
+		t.Fatalf("Unable to retrieve balance before block: %v", err)
+	}
+	after, err := t.Eth.BalanceAt(t.Ctx(), sender, receipt.BlockNumber)
+	if err != nil {
+		t.Fatalf("Unable to retrieve balance after block: %v", err)
+	}
+
+	delta := new(big.Int).Sub(before, after)
+	delta.Sub(delta, tx.Value())
+	delta.Sub(delta, new(big.Int).Mul(big.NewInt(int64(receipt.GasUsed)), tx.GasPrice()))
+	return delta
+}
+
+// sendAndCollectBlocks submits txs concurrently and returns the distinct
+// blocks they were mined into.
+func sendAndCollectBlocks(t *TestEnv, txs []*types.Transaction) []*types.Block {
+	receipts := make(chan *types.Receipt, len(txs))
+	var wg sync.WaitGroup
+	wg.Add(len(txs))
+
+	tCtx := t.Ctx()
+	for _, tx := range txs {
+		tx := tx
+		go func() {
+			defer wg.Done()
+			if err := t.Eth.SendTransaction(tCtx, tx); err != nil {
+				t.Fatalf("Error sending tx (%v): %v", tx.Hash(), err)
+			}
+			receipt, err := waitForReceipt(tCtx, t.Eth, tx.Hash())
+			if err != nil {
+				t.Fatalf("failed to wait for tx (%v) confirmation: %v", tx.Hash(), err)
+			}
+			receipts <- receipt
+		}()
+	}
+	wg.Wait()
+	close(receipts)
+
+	seen := make(map[uint64]bool)
+	var blocks []*types.Block
+	for receipt := range receipts {
+		blocknum := receipt.BlockNumber.Uint64()
+		if seen[blocknum] {
+			continue
+		}
+		seen[blocknum] = true
+		block, err := t.Eth.BlockByHash(t.Ctx(), receipt.BlockHash)
+		if err != nil {
+			t.Fatalf("Error getting block: %v", err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// mineEmptyBlock sends a plain value transfer (no blobs) and returns the
+// block it lands in, used to let excess data gas decay between bursts.
+func (t *TestEnv) mineEmptyBlock(sourceAddr common.Address, nonce uint64) *types.Block {
+	tx := types.NewTransaction(nonce, sourceAddr, big.NewInt(0), 21000, gasPrice, nil)
+	signedTx, err := t.Vault.signTransaction(sourceAddr, tx)
+	if err != nil {
+		t.Fatalf("Unable to sign idle tx: %v", err)
+	}
+	if err := t.Eth.SendTransaction(t.Ctx(), signedTx); err != nil {
+		t.Fatalf("Error sending idle tx (%v): %v", signedTx.Hash(), err)
+	}
+	receipt, err := waitForReceipt(t.Ctx(), t.Eth, signedTx.Hash())
+	if err != nil {
+		t.Fatalf("failed to wait for idle tx (%v) confirmation: %v", signedTx.Hash(), err)
+	}
+	block, err := t.Eth.BlockByHash(t.Ctx(), receipt.BlockHash)
+	if err != nil {
+		t.Fatalf("Error getting block: %v", err)
+	}
+	return block
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"math/rand"
 	"sort"
 	"sync"
 	"time"
@@ -17,34 +18,94 @@ import (
 	"github.com/protolambda/ztyp/view"
 )
 
+// maxBlobsPerTx and maxBlobsPerBlock mirror the EIP-4844 consensus-layer
+// parameters MAX_BLOBS_PER_TX and MAX_BLOBS_PER_BLOCK. Clients under test are
+// expected to enforce both limits.
+const (
+	maxBlobsPerTx    = 2
+	maxBlobsPerBlock = 16
+)
+
 func createBlobTransaction(t *TestEnv, nonce uint64, amount *big.Int, sourceAddr common.Address, targetAddr common.Address) *types.Transaction {
-	gasLimit := 210000
+	return createBlobTransactionN(t, nonce, 1, amount, sourceAddr, targetAddr)
+}
+
+// createBlobTransactionN behaves like createBlobTransaction but allows the
+// caller to control how many blobs are attached to the transaction. Blob
+// contents are deterministic pseudo-random field elements, keyed off nonce
+// and blob index, so that repeated test runs produce identical transactions.
+func createBlobTransactionN(t *TestEnv, nonce uint64, numBlobs int, amount *big.Int, sourceAddr common.Address, targetAddr common.Address) *types.Transaction {
+	return createBlobTransactionWithOpts(t, nonce, numBlobs, amount, sourceAddr, targetAddr, nil)
+}
+
+// blobTxOpts overrides the defaults createBlobTransactionN otherwise uses,
+// for test cases that need a deliberately mispriced or malformed blob
+// transaction without hand-rolling the whole SignedBlobTx/BlobTxWrapData
+// construction themselves.
+type blobTxOpts struct {
+	// maxFeePerDataGas overrides the default data-gas fee cap.
+	maxFeePerDataGas *big.Int
+	// gasTipCap overrides the default gasTipCap package variable.
+	gasTipCap *big.Int
+	// corruptVersionedHash, if true, flips every bit of the last byte of the
+	// first blob's versioned hash so it no longer matches its KZG commitment.
+	corruptVersionedHash bool
+}
+
+// createBlobTransactionWithOpts is the shared constructor behind
+// createBlobTransactionN; opts may be nil to use every default.
+//
+// This always builds the SSZ-encoded SignedBlobTx envelope: the pinned
+// go-ethereum fork (see engine/go.mod's replace directive) only implements
+// blob (type-5) transactions through that path and has no RLP-encoded
+// equivalent (no types.BlobTx). The RLP wire-format encoding is built
+// independently, by hand, in blob_rlp.go.
+func createBlobTransactionWithOpts(t *TestEnv, nonce uint64, numBlobs int, amount *big.Int, sourceAddr common.Address, targetAddr common.Address, opts *blobTxOpts) *types.Transaction {
+	maxFeePerDataGas := big.NewInt(3000000000) // needs to be at least the min fee
+	tipCap := gasTipCap
+	corruptVersionedHash := false
+	if opts != nil {
+		if opts.maxFeePerDataGas != nil {
+			maxFeePerDataGas = opts.maxFeePerDataGas
+		}
+		if opts.gasTipCap != nil {
+			tipCap = opts.gasTipCap
+		}
+		corruptVersionedHash = opts.corruptVersionedHash
+	}
 
 	var blobs types.Blobs
-	blobs = append(blobs, types.Blob{})
+	for i := 0; i < numBlobs; i++ {
+		blobs = append(blobs, randomBlob(nonce, i))
+	}
 	kzgCommitments, versionedHashes, aggregatedProof, err := blobs.ComputeCommitmentsAndAggregatedProof()
 	if err != nil {
 		t.Fatalf("unable to compute kzg commitments: %v", err)
 	}
+	if corruptVersionedHash {
+		versionedHashes[0][31] ^= 0xff
+	}
+	wrapData := types.BlobTxWrapData{
+		BlobKzgs:           kzgCommitments,
+		Blobs:              blobs,
+		KzgAggregatedProof: aggregatedProof,
+	}
+
 	txData := types.SignedBlobTx{
 		Message: types.BlobTxMessage{
 			ChainID:             view.MustUint256(chainID.String()),
 			Nonce:               view.Uint64View(nonce),
-			Gas:                 view.Uint64View(gasLimit),
+			Gas:                 view.Uint64View(210000),
 			GasFeeCap:           view.MustUint256(gasPrice.String()),
-			GasTipCap:           view.MustUint256(gasTipCap.String()),
-			MaxFeePerDataGas:    view.MustUint256("3000000000"), // needs to be at least the min fee
+			GasTipCap:           view.MustUint256(tipCap.String()),
+			MaxFeePerDataGas:    view.MustUint256(maxFeePerDataGas.String()),
 			Value:               view.MustUint256(amount.String()),
 			To:                  types.AddressOptionalSSZ{Address: (*types.AddressSSZ)(&targetAddr)},
 			BlobVersionedHashes: versionedHashes,
 		},
 	}
-	wrapData := types.BlobTxWrapData{
-		BlobKzgs:           kzgCommitments,
-		Blobs:              blobs,
-		KzgAggregatedProof: aggregatedProof,
-	}
 	rawTx := types.NewTx(&txData, types.WithTxWrapData(&wrapData))
+
 	tx, err := t.Vault.signTransaction(sourceAddr, rawTx)
 	if err != nil {
 		t.Fatalf("Unable to sign value tx: %v", err)
@@ -52,6 +113,19 @@ func createBlobTransaction(t *TestEnv, nonce uint64, amount *big.Int, sourceAddr
 	return tx
 }
 
+// randomBlob fills a blob with deterministic pseudo-random field elements.
+// Each 32-byte field element has its top byte cleared so that the value is
+// guaranteed to be canonical (less than the BLS12-381 scalar field modulus).
+func randomBlob(nonce uint64, index int) types.Blob {
+	src := rand.New(rand.NewSource(int64(nonce)<<16 | int64(index)))
+	var blob types.Blob
+	for i := range blob {
+		src.Read(blob[i][:])
+		blob[i][0] = 0
+	}
+	return blob
+}
+
 // blobTransactionTest creates a blob transaction. Then asserts that it is
 // included in a block
 func blobTransactionTest(t *TestEnv) {